@@ -25,19 +25,26 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	pprofhttp "net/http/pprof"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+	"github.com/soheilhy/cmux"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/Arriven/db1000n/src/job"
 	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/job/remote"
 	"github.com/Arriven/db1000n/src/utils"
 	"github.com/Arriven/db1000n/src/utils/metrics"
 	"github.com/Arriven/db1000n/src/utils/ota"
@@ -52,6 +59,7 @@ func main() {
 	countryCheckerConfig := utils.NewCountryCheckerConfigWithFlags()
 	updaterMode, destinationPath := config.NewUpdaterOptionsWithFlags()
 	prometheusOn, prometheusListenAddress := metrics.NewOptionsWithFlags()
+	remoteControlConfig := remote.NewConfigWithFlags()
 	pprof := flag.String("pprof", utils.GetEnvStringDefault("GO_PPROF_ENDPOINT", ""), "enable pprof")
 	help := flag.Bool("h", false, "print help message and exit")
 	version := flag.Bool("version", false, "print version and exit")
@@ -63,10 +71,18 @@ func main() {
 	lessStats := flag.Bool("less-stats", utils.GetEnvBoolDefault("LESS_STATS", false), "group target stats by protocols - in case you have too many targets")
 	periodicGCEnabled := flag.Bool("periodic-gc", utils.GetEnvBoolDefault("PERIODIC_GC", false),
 		"set to true if you want to run periodic garbage collection(useful in pooling scenarios, like db1000nx100)")
+	logFile := flag.String("log-file", utils.GetEnvStringDefault("LOG_FILE", ""),
+		"strftime-templated path to write logs to in addition to stderr, e.g. /var/log/db1000n/db1000n.%Y%m%d.log, leave empty to disable")
+	logFileMaxSize := flag.Int("log-file-max-size", utils.GetEnvIntDefault("LOG_FILE_MAX_SIZE", 100),
+		"max size of a single log file in megabytes before it gets rotated")
+	logFileMaxAge := flag.Duration("log-file-max-age", utils.GetEnvDurationDefault("LOG_FILE_MAX_AGE", 7*24*time.Hour),
+		"how long to keep rotated log files around before they get removed")
+	logFileRotationTime := flag.Duration("log-file-rotation-time", utils.GetEnvDurationDefault("LOG_FILE_ROTATION_TIME", 24*time.Hour),
+		"how often to rotate the log file regardless of its size")
 
 	flag.Parse()
 
-	logger, err := newZapLogger(*debug, *logLevel, *logFormat)
+	logger, closeLogFile, err := newZapLogger(*debug, *logLevel, *logFormat, *logFile, *logFileMaxSize, *logFileMaxAge, *logFileRotationTime)
 	if err != nil {
 		panic(err)
 	}
@@ -94,17 +110,54 @@ func main() {
 
 	go periodicGC(periodicGCEnabled, runnerConfigOptions.RefreshTimeout, logger)
 	go ota.WatchUpdates(logger, otaConfig)
-	setUpPprof(logger, *pprof, *debug)
+	setUpPprofAndControlPlaneHealth(logger, *pprof, *debug, remoteControlConfig)
 	rand.Seed(time.Now().UnixNano())
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	cancel := func() {
+		cancelCtx()
+		closeLogFile()
+	}
 	defer cancel()
 
 	metrics.InitOrFail(ctx, logger, *prometheusOn, *prometheusListenAddress, jobsGlobalConfig.ClientID,
 		utils.CheckCountryOrFail(ctx, logger, countryCheckerConfig, jobsGlobalConfig.GetProxyParams(logger, nil)))
+
+	remoteControlClient, err := remote.NewClient(ctx, logger, remoteControlConfig, jobsGlobalConfig.ClientID)
+	if err != nil {
+		logger.Warn("failed to start control-plane client", zap.Error(err))
+	}
+
+	if remoteControlClient != nil {
+		runnerConfigOptions.RemoteConfigSource = remote.NewConfigSource(remoteControlClient)
+	}
+
 	job.NewRunner(runnerConfigOptions, jobsGlobalConfig, newReporter(*logFormat, *lessStats, logger)).Run(ctx, logger)
 }
 
+// defaultRemoteControlHealthAddr is used when remote control is enabled but the operator gave
+// us no health address to bind and pprof is off too, so there's no other address to fall back to.
+const defaultRemoteControlHealthAddr = ":8090"
+
+// remoteControlHealthAddr defaults the control-plane health server to the pprof endpoint so
+// orchestrators only need to probe one address, unless the operator set one explicitly. If pprof
+// is also off, it falls back to defaultRemoteControlHealthAddr instead of silently serving no
+// health endpoint at all.
+func remoteControlHealthAddr(logger *zap.Logger, cfg *remote.Config, pprof string) string {
+	if !cfg.Enabled || cfg.HealthAddr != "" {
+		return cfg.HealthAddr
+	}
+
+	if pprof != "" {
+		return pprof
+	}
+
+	logger.Warn("remote control enabled with no -remote-control-health-addr and no -pprof, "+
+		"defaulting control-plane health server address", zap.String("addr", defaultRemoteControlHealthAddr))
+
+	return defaultRemoteControlHealthAddr
+}
+
 func periodicGC(enabled *bool, period time.Duration, log *zap.Logger) {
 	if !*enabled {
 		return
@@ -130,12 +183,22 @@ func periodicGC(enabled *bool, period time.Duration, log *zap.Logger) {
 	}
 }
 
-func newZapLogger(debug bool, logLevel string, logFormat string) (*zap.Logger, error) {
+// newZapLogger builds the root logger. When logFile is non-empty, log records are teed into a
+// rotating file sink (by size and by age) in addition to the usual stderr output. The returned
+// close func flushes and closes the rotating writer and must be called on shutdown.
+func newZapLogger(debug bool, logLevel, logFormat, logFile string, logFileMaxSizeMB int, logFileMaxAge, logFileRotationTime time.Duration) (
+	*zap.Logger, func(), error,
+) {
 	cfg := zap.NewProductionConfig()
 	if debug {
 		cfg = zap.NewDevelopmentConfig()
 	}
 
+	// snapshot before simpleLogFormat strips keys off cfg.EncoderConfig below: the rotating file
+	// sink is for forensics and must keep timestamps/caller/etc regardless of what the console
+	// display format strips, since simple is the default -log-format.
+	fileEncoderConfig := cfg.EncoderConfig
+
 	if logFormat == simpleLogFormat {
 		// turn off all output except the message itself and log level
 		cfg.Encoding = "console"
@@ -160,17 +223,150 @@ func newZapLogger(debug bool, logLevel string, logFormat string) (*zap.Logger, e
 		cfg.Level = level
 	}
 
-	return cfg.Build()
+	noop := func() {}
+
+	if logFile == "" {
+		logger, err := cfg.Build()
+
+		return logger, noop, err
+	}
+
+	consoleCore, err := buildConsoleCore(cfg)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	fileWriter, err := rotatelogs.New(
+		logFile,
+		rotatelogs.WithLinkName(currentLogLinkName(logFile)),
+		rotatelogs.WithRotationSize(int64(logFileMaxSizeMB)*1024*1024),
+		rotatelogs.WithMaxAge(logFileMaxAge),
+		rotatelogs.WithRotationTime(logFileRotationTime),
+	)
+	if err != nil {
+		return nil, noop, fmt.Errorf("error opening rotating log file %q: %w", logFile, err)
+	}
+
+	fileEncoder := zapcore.NewJSONEncoder(fileEncoderConfig)
+	fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(fileWriter), cfg.Level)
+
+	errSink, _, err := zap.Open(cfg.ErrorOutputPaths...)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	logger := zap.New(zapcore.NewTee(consoleCore, fileCore), zapOptions(cfg, errSink)...)
+
+	return logger, func() { fileWriter.Close() }, nil
+}
+
+// zapOptions mirrors the option set zap.Config.Build() derives from cfg (caller/stacktrace,
+// sampling, initial fields, error output), since that logic is unexported and we need it applied
+// to our own teed core instead of the one cfg.Build() would construct internally.
+func zapOptions(cfg zap.Config, errSink zapcore.WriteSyncer) []zap.Option {
+	opts := []zap.Option{zap.ErrorOutput(errSink)}
+
+	if cfg.Development {
+		opts = append(opts, zap.Development())
+	}
+
+	if !cfg.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+
+	stackLevel := zapcore.ErrorLevel
+	if cfg.Development {
+		stackLevel = zapcore.WarnLevel
+	}
+
+	if !cfg.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+
+	if sampling := cfg.Sampling; sampling != nil {
+		opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			var samplerOpts []zapcore.SamplerOption
+			if sampling.Hook != nil {
+				samplerOpts = append(samplerOpts, zapcore.SamplerHook(sampling.Hook))
+			}
+
+			return zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter, samplerOpts...)
+		}))
+	}
+
+	if len(cfg.InitialFields) > 0 {
+		keys := make([]string, 0, len(cfg.InitialFields))
+		for k := range cfg.InitialFields {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		fields := make([]zap.Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, zap.Any(k, cfg.InitialFields[k]))
+		}
+
+		opts = append(opts, zap.Fields(fields...))
+	}
+
+	return opts
+}
+
+// buildConsoleCore builds the console-only core out of a zap config without going through
+// cfg.Build(), so it can be teed together with the rotating file core.
+func buildConsoleCore(cfg zap.Config) (zapcore.Core, error) {
+	sink, _, err := zap.Open(cfg.OutputPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := newEncoder(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return zapcore.NewCore(encoder, sink, cfg.Level), nil
 }
 
-func setUpPprof(logger *zap.Logger, pprof string, debug bool) {
+// currentLogLinkName turns a strftime-templated log path (e.g. db1000n.%Y%m%d.log) into the
+// fixed name of the symlink that always points at the currently active log file.
+func currentLogLinkName(logFile string) string {
+	if i := strings.IndexByte(logFile, '%'); i >= 0 {
+		return strings.TrimRight(logFile[:i], ".-_") + filepath.Ext(strings.ReplaceAll(logFile, "%", ""))
+	}
+
+	return logFile
+}
+
+func newEncoder(cfg zap.Config) (zapcore.Encoder, error) {
+	switch cfg.Encoding {
+	case "console":
+		return zapcore.NewConsoleEncoder(cfg.EncoderConfig), nil
+	case "json":
+		return zapcore.NewJSONEncoder(cfg.EncoderConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown log encoding: %v", cfg.Encoding)
+	}
+}
+
+// setUpPprofAndControlPlaneHealth starts the pprof HTTP server and, if remoteControlConfig is
+// enabled, the gRPC control-plane health server. When the two are configured to share an
+// address (the default: remoteControlConfig.HealthAddr left empty falls back to pprof), they
+// can't each open their own listener on it, so the shared port is split with cmux based on
+// whether the incoming connection speaks gRPC (HTTP/2 + "application/grpc") or plain HTTP/1.1.
+func setUpPprofAndControlPlaneHealth(logger *zap.Logger, pprof string, debug bool, remoteControlConfig *remote.Config) {
 	switch {
 	case debug && pprof == "":
 		pprof = ":8080"
 	case pprof == "":
+		serveStandaloneHealth(logger, remoteControlHealthAddr(logger, remoteControlConfig, pprof))
+
 		return
 	}
 
+	healthAddr := remoteControlHealthAddr(logger, remoteControlConfig, pprof)
+
 	mux := http.NewServeMux()
 	mux.Handle("/debug/pprof/", http.HandlerFunc(pprofhttp.Index))
 	mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprofhttp.Cmdline))
@@ -178,15 +374,63 @@ func setUpPprof(logger *zap.Logger, pprof string, debug bool) {
 	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprofhttp.Symbol))
 	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprofhttp.Trace))
 
-	server := &http.Server{
-		Addr:         pprof,
+	httpServer := &http.Server{
 		Handler:      mux,
 		ReadTimeout:  time.Second,
 		WriteTimeout: time.Second,
 	}
 
+	if healthAddr != "" && healthAddr != pprof {
+		// dedicated port for health: no sharing, no multiplexing needed
+		if lis := mustListen(logger, pprof); lis != nil {
+			go func() { logger.Warn("pprof server", zap.Error(httpServer.Serve(lis))) }()
+		}
+
+		serveStandaloneHealth(logger, healthAddr)
+
+		return
+	}
+
+	lis := mustListen(logger, pprof)
+	if lis == nil {
+		return
+	}
+
+	if healthAddr == "" {
+		// no control-plane health requested at all: plain pprof, no muxing overhead
+		go func() { logger.Warn("pprof server", zap.Error(httpServer.Serve(lis))) }()
+
+		return
+	}
+
+	m := cmux.New(lis)
+	grpcListener := m.Match(cmux.HTTP2HeaderField("content-type", "application/grpc"))
+	httpListener := m.Match(cmux.Any())
+
+	remote.ServeHealth(logger, grpcListener)
+
+	go func() { logger.Warn("pprof server", zap.Error(httpServer.Serve(httpListener))) }()
 	// this has to be wrapped into a lambda bc otherwise it blocks when evaluating argument for zap.Error
-	go func() { logger.Warn("pprof server", zap.Error(server.ListenAndServe())) }()
+	go func() { logger.Warn("pprof/control-plane-health mux", zap.Error(m.Serve())) }()
+}
+
+func serveStandaloneHealth(logger *zap.Logger, healthAddr string) {
+	if healthAddr == "" {
+		return
+	}
+
+	remote.ServeHealth(logger, mustListen(logger, healthAddr))
+}
+
+func mustListen(logger *zap.Logger, addr string) net.Listener {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Warn("tcp listener", zap.String("addr", addr), zap.Error(err))
+
+		return nil
+	}
+
+	return lis
 }
 
 func newReporter(logFormat string, groupTargets bool, logger *zap.Logger) metrics.Reporter {
@@ -0,0 +1,221 @@
+//go:build quic
+
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package packetgen
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/quic-go/quic-go"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+func init() {
+	RegisterConnectionType("quic", func(ctx context.Context, args map[string]any, proxy *utils.ProxyParams) (Connection, error) {
+		var cfg quicConnConfig
+		if err := utils.Decode(args, &cfg); err != nil {
+			return nil, fmt.Errorf("error decoding connection config: %w", err)
+		}
+
+		return openQuicConn(ctx, cfg, proxy)
+	})
+}
+
+// quicSessionCache caches 0-RTT resumption state per target host so repeated OpenConnection
+// calls against the same host can skip the full handshake.
+var quicSessionCache = struct {
+	sync.Mutex
+	m map[string]tls.ClientSessionCache
+}{m: map[string]tls.ClientSessionCache{}}
+
+func quicSessionCacheFor(host string) tls.ClientSessionCache {
+	quicSessionCache.Lock()
+	defer quicSessionCache.Unlock()
+
+	cache, ok := quicSessionCache.m[host]
+	if !ok {
+		cache = tls.NewLRUClientSessionCache(1)
+		quicSessionCache.m[host] = cache
+	}
+
+	return cache
+}
+
+type quicConnConfig struct {
+	Address         string
+	TLSClientConfig *tls.Config
+	NextProtos      []string
+	MaxIdleTimeout  time.Duration
+	Enable0RTT      bool
+}
+
+type quicConn struct {
+	pconn  net.PacketConn
+	conn   *quic.Conn
+	stream *quic.Stream
+	buf    gopacket.SerializeBuffer
+
+	host   string
+	target string
+}
+
+// openQuicConn opens a QUIC session to c.Address and a single bidirectional stream on top of
+// it, so jobs can flood HTTP/3 or raw QUIC-framed traffic without paying the TCP handshake cost.
+// proxyParams is honored through a UDP-capable proxy dialer, falling back to a direct UDP socket if
+// the configured proxy scheme can't carry UDP.
+func openQuicConn(ctx context.Context, c quicConnConfig, proxyParams *utils.ProxyParams) (*quicConn, error) {
+	host, _, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		host = c.Address
+	}
+
+	pconn, remoteAddr, err := quicPacketConn(ctx, c.Address, proxyParams)
+	if err != nil {
+		return nil, fmt.Errorf("error opening quic transport: %w", err)
+	}
+
+	tlsConfig := c.TLSClientConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+
+	tlsConfig = tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+
+	if len(c.NextProtos) > 0 {
+		tlsConfig.NextProtos = c.NextProtos
+	}
+
+	if c.Enable0RTT {
+		tlsConfig.ClientSessionCache = quicSessionCacheFor(host)
+	}
+
+	quicConfig := &quic.Config{
+		MaxIdleTimeout: c.MaxIdleTimeout,
+		Allow0RTT:      c.Enable0RTT,
+	}
+
+	tr := &quic.Transport{Conn: pconn}
+
+	var session *quic.Conn
+	if c.Enable0RTT {
+		session, err = tr.DialEarly(ctx, remoteAddr, tlsConfig, quicConfig)
+	} else {
+		session, err = tr.Dial(ctx, remoteAddr, tlsConfig, quicConfig)
+	}
+
+	if err != nil {
+		pconn.Close()
+
+		return nil, fmt.Errorf("error dialing quic session: %w", err)
+	}
+
+	stream, err := session.OpenStream()
+	if err != nil {
+		session.CloseWithError(0, "")
+		pconn.Close()
+
+		return nil, fmt.Errorf("error opening quic stream: %w", err)
+	}
+
+	return &quicConn{
+		pconn:  pconn,
+		conn:   session,
+		stream: stream,
+		buf:    gopacket.NewSerializeBuffer(),
+		host:   host,
+		target: "quic://" + c.Address,
+	}, nil
+}
+
+func (conn *quicConn) Write(packet Packet) (n int, err error) {
+	if err := packet.Serialize(conn.buf); err != nil {
+		return 0, fmt.Errorf("error serializing packet: %w", err)
+	}
+
+	return conn.stream.Write(conn.buf.Bytes())
+}
+
+func (conn *quicConn) Read(buf []byte) (int, error) { return conn.stream.Read(buf) }
+
+func (conn *quicConn) Close() error {
+	// Deliberately leave conn.host's entry in quicSessionCache alone: a flood job's normal
+	// lifecycle is open->use->close before the next OpenConnection to the same host, and
+	// evicting here would throw away the 0-RTT ticket the very next connection needs. The
+	// per-host tls.NewLRUClientSessionCache(1) already bounds memory per entry.
+	conn.stream.Close()
+	conn.conn.CloseWithError(0, "")
+
+	return conn.pconn.Close()
+}
+
+func (conn *quicConn) Target() string { return conn.target }
+
+// quicPacketConn resolves a net.PacketConn to send QUIC datagrams over. It first tries routing
+// through proxyParams via a UDP-capable dialer, and falls back to a direct UDP socket when the
+// configured proxy scheme doesn't support UDP (e.g. plain HTTP/SOCKS-over-TCP proxies).
+func quicPacketConn(ctx context.Context, address string, proxyParams *utils.ProxyParams) (net.PacketConn, net.Addr, error) {
+	remoteAddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving quic target address: %w", err)
+	}
+
+	if dialFunc := utils.GetProxyFunc(ctx, utils.NonNilOrDefault(proxyParams, utils.ProxyParams{}), "udp"); dialFunc != nil {
+		if conn, err := dialFunc("udp", address); err == nil {
+			return &packetConnFromConn{Conn: conn}, remoteAddr, nil
+		}
+	}
+
+	pconn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening direct udp socket: %w", err)
+	}
+
+	return pconn, remoteAddr, nil
+}
+
+// packetConnFromConn adapts a connected net.Conn (as returned by proxy dialers that only speak
+// the dial(network, address) net.Conn shape) into the net.PacketConn quic.Transport expects.
+type packetConnFromConn struct {
+	net.Conn
+}
+
+func (c *packetConnFromConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, err := c.Conn.Read(p)
+
+	return n, c.Conn.RemoteAddr(), err
+}
+
+func (c *packetConnFromConn) WriteTo(p []byte, _ net.Addr) (int, error) {
+	return c.Conn.Write(p)
+}
@@ -0,0 +1,49 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package packetgen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+func TestOpenConnectionUnknownType(t *testing.T) {
+	_, err := OpenConnection(context.Background(), ConnectionConfig{Type: "bogus"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown connection type, got nil")
+	}
+}
+
+func TestRegisterConnectionTypeDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic on duplicate registration, got none")
+		}
+	}()
+
+	RegisterConnectionType("raw", func(context.Context, map[string]any, *utils.ProxyParams) (Connection, error) {
+		return nil, nil
+	})
+}
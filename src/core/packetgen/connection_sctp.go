@@ -0,0 +1,107 @@
+//go:build sctp
+
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package packetgen
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/gopacket"
+	"github.com/pion/sctp"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+func init() {
+	RegisterConnectionType("sctp", func(ctx context.Context, args map[string]any, proxy *utils.ProxyParams) (Connection, error) {
+		var cfg sctpConnConfig
+		if err := utils.Decode(args, &cfg); err != nil {
+			return nil, fmt.Errorf("error decoding connection config: %w", err)
+		}
+
+		return openSctpConn(ctx, cfg, proxy)
+	})
+}
+
+type sctpConnConfig struct {
+	Address  string
+	StreamID uint16
+}
+
+type sctpConn struct {
+	assoc  *sctp.Association
+	stream *sctp.Stream
+	buf    gopacket.SerializeBuffer
+
+	target string
+}
+
+// openSctpConn dials the underlying transport the same way OpenNetConn does (proxy-aware via
+// utils.GetProxyFunc) and establishes a single SCTP association and stream on top of it.
+func openSctpConn(ctx context.Context, c sctpConnConfig, proxyParams *utils.ProxyParams) (*sctpConn, error) {
+	conn, err := utils.GetProxyFunc(ctx, utils.NonNilOrDefault(proxyParams, utils.ProxyParams{}), "udp")("udp", c.Address)
+	if err != nil {
+		return nil, fmt.Errorf("error dialing sctp transport: %w", err)
+	}
+
+	assoc, err := sctp.Client(sctp.Config{NetConn: conn})
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("error establishing sctp association: %w", err)
+	}
+
+	stream, err := assoc.OpenStream(c.StreamID, sctp.PayloadTypeWebRTCBinary)
+	if err != nil {
+		assoc.Close()
+
+		return nil, fmt.Errorf("error opening sctp stream: %w", err)
+	}
+
+	return &sctpConn{
+		assoc:  assoc,
+		stream: stream,
+		buf:    gopacket.NewSerializeBuffer(),
+		target: "sctp://" + c.Address,
+	}, nil
+}
+
+func (conn *sctpConn) Write(packet Packet) (n int, err error) {
+	if err := packet.Serialize(conn.buf); err != nil {
+		return 0, fmt.Errorf("error serializing packet: %w", err)
+	}
+
+	return conn.stream.Write(conn.buf.Bytes())
+}
+
+func (conn *sctpConn) Read(buf []byte) (int, error) { return conn.stream.Read(buf) }
+
+func (conn *sctpConn) Close() error {
+	conn.stream.Close()
+
+	return conn.assoc.Close()
+}
+
+func (conn *sctpConn) Target() string { return conn.target }
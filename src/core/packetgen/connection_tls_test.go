@@ -0,0 +1,176 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package packetgen
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// generateTestCert returns a self-signed cert/key PEM pair and the base64 SHA-256 SPKI pin that
+// matches it, so tests can exercise ClientCertPEM/RootCAsPEM/SPKIPins without fixtures on disk.
+func generateTestCert(t *testing.T) (certPEM, keyPEM, spkiPin string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating test key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "db1000n-test"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(0, 0).Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("creating test cert: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling test key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing test cert: %v", err)
+	}
+
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	spkiPin = base64.StdEncoding.EncodeToString(sum[:])
+
+	return certPEM, keyPEM, spkiPin
+}
+
+func TestBuildTLSConfigNoFieldsReturnsNil(t *testing.T) {
+	cfg, err := (netConnConfig{}).buildTLSConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg != nil {
+		t.Fatalf("expected a nil *tls.Config when no TLS fields are set, got %+v", cfg)
+	}
+}
+
+func TestBuildTLSConfigDeclarativeFields(t *testing.T) {
+	certPEM, keyPEM, _ := generateTestCert(t)
+
+	cfg, err := (netConnConfig{
+		ClientCertPEM:      certPEM,
+		ClientKeyPEM:       keyPEM,
+		RootCAsPEM:         certPEM,
+		ServerName:         "example.com",
+		InsecureSkipVerify: true,
+		MinVersion:         "1.2",
+		MaxVersion:         "1.3",
+	}).buildTLSConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	switch {
+	case cfg == nil:
+		t.Fatal("expected a non-nil *tls.Config")
+	case cfg.ServerName != "example.com":
+		t.Errorf("ServerName = %q, want %q", cfg.ServerName, "example.com")
+	case !cfg.InsecureSkipVerify:
+		t.Error("InsecureSkipVerify = false, want true")
+	case len(cfg.Certificates) != 1:
+		t.Errorf("len(Certificates) = %d, want 1", len(cfg.Certificates))
+	case cfg.RootCAs == nil:
+		t.Error("RootCAs = nil, want a pool built from RootCAsPEM")
+	case cfg.MinVersion != tls.VersionTLS12:
+		t.Errorf("MinVersion = %x, want VersionTLS12", cfg.MinVersion)
+	case cfg.MaxVersion != tls.VersionTLS13:
+		t.Errorf("MaxVersion = %x, want VersionTLS13", cfg.MaxVersion)
+	}
+}
+
+func TestBuildTLSConfigInvalidClientCert(t *testing.T) {
+	_, err := (netConnConfig{ClientCertPEM: "not a pem", ClientKeyPEM: "not a pem"}).buildTLSConfig()
+	if err == nil {
+		t.Fatal("expected an error for an invalid client cert/key pair, got nil")
+	}
+}
+
+func TestBuildTLSConfigInvalidRootCAs(t *testing.T) {
+	_, err := (netConnConfig{RootCAsPEM: "not a pem"}).buildTLSConfig()
+	if err == nil {
+		t.Fatal("expected an error for an invalid RootCAsPEM, got nil")
+	}
+}
+
+func TestBuildTLSConfigInvalidVersion(t *testing.T) {
+	for _, field := range []netConnConfig{
+		{MinVersion: "1.4"},
+		{MaxVersion: "bogus"},
+	} {
+		if _, err := field.buildTLSConfig(); err == nil {
+			t.Errorf("buildTLSConfig(%+v): expected an error for an invalid TLS version, got nil", field)
+		}
+	}
+}
+
+func TestSPKIPinVerifierMatch(t *testing.T) {
+	certPEM, _, pin := generateTestCert(t)
+
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		t.Fatal("failed to decode generated test cert PEM")
+	}
+
+	if err := spkiPinVerifier([]string{pin})([][]byte{block.Bytes}, nil); err != nil {
+		t.Errorf("expected the matching pin to verify, got: %v", err)
+	}
+}
+
+func TestSPKIPinVerifierMismatch(t *testing.T) {
+	certPEM, _, _ := generateTestCert(t)
+
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		t.Fatal("failed to decode generated test cert PEM")
+	}
+
+	err := spkiPinVerifier([]string{"not-the-real-pin"})([][]byte{block.Bytes}, nil)
+	if err == nil {
+		t.Fatal("expected an error when no presented cert matches the configured pins, got nil")
+	}
+}
@@ -0,0 +1,289 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Client maintains a long-lived Sync stream to the control-plane coordinator, feeding
+// server-pushed JobConfig updates to Configs() and forwarding TargetStats/heartbeats back.
+type Client struct {
+	cfg    *Config
+	logger *zap.Logger
+	conn   *grpc.ClientConn
+	rpc    RemoteControlClient
+
+	configs chan *JobConfig
+	stats   chan *TargetStats
+}
+
+// statsQueueSize bounds how many ReportStats calls can be buffered while waiting for the send
+// loop to flush them onto the stream; callers on the hot path must never block on a slow or
+// reconnecting coordinator.
+const statsQueueSize = 256
+
+// NewClient dials the coordinator and starts the background Sync loop. It is a no-op (returns
+// nil, nil) when cfg.Enabled is false so callers can wire it unconditionally.
+func NewClient(ctx context.Context, logger *zap.Logger, cfg *Config, clientID string) (*Client, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	creds, err := cfg.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("error building control-plane TLS credentials: %w", err)
+	}
+
+	conn, err := grpc.NewClient(cfg.Endpoint,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(tokenCredentials{token: cfg.Token, insecure: cfg.Insecure}),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error dialing control-plane endpoint %q: %w", cfg.Endpoint, err)
+	}
+
+	c := &Client{
+		cfg:     cfg,
+		logger:  logger,
+		conn:    conn,
+		rpc:     NewRemoteControlClient(conn),
+		configs: make(chan *JobConfig),
+		stats:   make(chan *TargetStats, statsQueueSize),
+	}
+
+	go c.syncLoop(ctx, clientID)
+
+	return c, nil
+}
+
+// Configs streams JobConfig updates pushed by the coordinator, replacing the HTTP polling loop
+// for as long as the underlying stream stays connected.
+func (c *Client) Configs() <-chan *JobConfig {
+	if c == nil {
+		return nil
+	}
+
+	return c.configs
+}
+
+// ReportStats queues a single target's stats to be sent on the next open stream's send loop,
+// augmenting the local Prometheus push with whatever the coordinator wants to aggregate
+// fleet-wide. It never blocks the caller: if the queue is full (coordinator unreachable or
+// stream mid-reconnect) the update is dropped and logged rather than backing up the reporter's
+// hot path. Safe to call on a nil Client so callers can wire it unconditionally.
+func (c *Client) ReportStats(stats *TargetStats) {
+	if c == nil {
+		return
+	}
+
+	select {
+	case c.stats <- stats:
+	default:
+		c.logger.Warn("control-plane stats queue full, dropping update", zap.String("target", stats.GetTarget()))
+	}
+}
+
+func (c *Client) syncLoop(ctx context.Context, clientID string) {
+	defer close(c.configs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.conn.Close()
+
+			return
+		default:
+		}
+
+		if err := c.runStream(ctx, clientID); err != nil {
+			c.logger.Warn("control-plane stream failed, reconnecting", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.ReconnectBackoff):
+		}
+	}
+}
+
+func (c *Client) runStream(ctx context.Context, clientID string) error {
+	stream, err := c.rpc.Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("error opening control-plane stream: %w", err)
+	}
+
+	// gRPC streams allow one concurrent Send and one concurrent Recv but not two concurrent
+	// Sends, so the periodic heartbeat and queued stats share sendLoop as the only sender while
+	// this goroutine stays the only receiver.
+	sendCtx, cancelSend := context.WithCancel(ctx)
+	defer cancelSend()
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- c.sendLoop(sendCtx, stream, clientID) }()
+
+	for {
+		cfg, err := stream.Recv()
+		if err != nil {
+			cancelSend()
+			<-sendErr
+
+			return fmt.Errorf("error receiving job config: %w", err)
+		}
+
+		select {
+		case c.configs <- cfg:
+		case <-ctx.Done():
+			cancelSend()
+			<-sendErr
+
+			return nil
+		}
+	}
+}
+
+// sendLoop owns stream.Send for the lifetime of one connection: it sends an initial
+// ClientHeartbeat, then keeps sending one every cfg.HeartbeatInterval so the coordinator can
+// tell a quiet-but-alive worker from a dead one, interleaving any TargetStats queued via
+// ReportStats as they arrive.
+func (c *Client) sendLoop(ctx context.Context, stream RemoteControl_SyncClient, clientID string) error {
+	if err := stream.Send(&ControlMessage{Payload: &ControlMessage_Heartbeat{
+		Heartbeat: &ClientHeartbeat{ClientID: clientID},
+	}}); err != nil {
+		return fmt.Errorf("error sending initial heartbeat: %w", err)
+	}
+
+	ticker := time.NewTicker(c.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := stream.Send(&ControlMessage{Payload: &ControlMessage_Heartbeat{
+				Heartbeat: &ClientHeartbeat{ClientID: clientID},
+			}}); err != nil {
+				return fmt.Errorf("error sending heartbeat: %w", err)
+			}
+		case stats := <-c.stats:
+			if err := stream.Send(&ControlMessage{Payload: &ControlMessage_Stats{Stats: stats}}); err != nil {
+				return fmt.Errorf("error sending stats: %w", err)
+			}
+		}
+	}
+}
+
+// transportCredentials builds TLS transport credentials out of the client cert/key, CA bundle
+// and insecure flag, matching the declarative style netConnConfig uses for job-level TLS.
+func (cfg *Config) transportCredentials() (credentials.TransportCredentials, error) {
+	if cfg.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: serverNameFromEndpoint(cfg.Endpoint)}
+
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading control-plane client cert: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading control-plane CA bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %q", cfg.CACertFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func serverNameFromEndpoint(endpoint string) string {
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+
+	return host
+}
+
+// tokenCredentials sends cfg.Token as a bearer token on every RPC, letting the coordinator
+// authenticate workers independently of (or in addition to) mTLS.
+type tokenCredentials struct {
+	token    string
+	insecure bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	if t.token == "" {
+		return nil, nil
+	}
+
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool { return !t.insecure }
+
+// ServeHealth runs a grpc.health.v1 health server on lis so orchestrators can liveness-check
+// this worker the same way they'd probe the coordinator. lis may be a dedicated listener or one
+// half of a cmux split sharing a port with another server (see main.go, which is how "same
+// endpoint as pprof" is actually achieved); ServeHealth itself stays agnostic of that. A nil lis
+// is a no-op so callers can wire it unconditionally.
+func ServeHealth(logger *zap.Logger, lis net.Listener) {
+	if lis == nil {
+		return
+	}
+
+	srv := grpc.NewServer()
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	go func() { logger.Warn("control-plane health server", zap.Error(srv.Serve(lis))) }()
+}
@@ -0,0 +1,119 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"testing"
+)
+
+func TestServerNameFromEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "coordinator.example.com:443", want: "coordinator.example.com"},
+		{endpoint: "10.0.0.1:8443", want: "10.0.0.1"},
+		{endpoint: "no-port-here", want: "no-port-here"},
+	}
+
+	for _, tt := range tests {
+		if got := serverNameFromEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("serverNameFromEndpoint(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestTransportCredentialsInsecure(t *testing.T) {
+	cfg := &Config{Insecure: true}
+
+	creds, err := cfg.transportCredentials()
+	if err != nil {
+		t.Fatalf("transportCredentials: %v", err)
+	}
+
+	if creds.Info().SecurityProtocol != "insecure" {
+		t.Errorf("SecurityProtocol = %q, want %q", creds.Info().SecurityProtocol, "insecure")
+	}
+}
+
+func TestTransportCredentialsTLS(t *testing.T) {
+	cfg := &Config{Endpoint: "coordinator.example.com:443"}
+
+	creds, err := cfg.transportCredentials()
+	if err != nil {
+		t.Fatalf("transportCredentials: %v", err)
+	}
+
+	if creds.Info().SecurityProtocol != "tls" {
+		t.Errorf("SecurityProtocol = %q, want %q", creds.Info().SecurityProtocol, "tls")
+	}
+}
+
+func TestTransportCredentialsInvalidClientCert(t *testing.T) {
+	cfg := &Config{TLSCertFile: "/nonexistent/cert.pem", TLSKeyFile: "/nonexistent/key.pem"}
+
+	if _, err := cfg.transportCredentials(); err == nil {
+		t.Fatal("expected an error for a missing client cert/key pair, got nil")
+	}
+}
+
+func TestTransportCredentialsInvalidCACert(t *testing.T) {
+	cfg := &Config{CACertFile: "/nonexistent/ca.pem"}
+
+	if _, err := cfg.transportCredentials(); err == nil {
+		t.Fatal("expected an error for a missing CA bundle, got nil")
+	}
+}
+
+func TestTokenCredentials(t *testing.T) {
+	creds := tokenCredentials{token: "secret", insecure: false}
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata: %v", err)
+	}
+
+	if md["authorization"] != "Bearer secret" {
+		t.Errorf("authorization = %q, want %q", md["authorization"], "Bearer secret")
+	}
+
+	if !creds.RequireTransportSecurity() {
+		t.Error("RequireTransportSecurity() = false, want true when insecure is false")
+	}
+
+	empty := tokenCredentials{insecure: true}
+
+	md, err = empty.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata: %v", err)
+	}
+
+	if md != nil {
+		t.Errorf("GetRequestMetadata() = %+v, want nil when token is empty", md)
+	}
+
+	if empty.RequireTransportSecurity() {
+		t.Error("RequireTransportSecurity() = true, want false when insecure is true")
+	}
+}
@@ -0,0 +1,49 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import "testing"
+
+func TestJSONCodecName(t *testing.T) {
+	if got := (jsonCodec{}).Name(); got != "json" {
+		t.Errorf("Name() = %q, want %q", got, "json")
+	}
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	want := &ControlMessage{Payload: &ControlMessage_Heartbeat{Heartbeat: &ClientHeartbeat{ClientID: "c1"}}}
+
+	data, err := (jsonCodec{}).Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got ControlMessage
+	if err := (jsonCodec{}).Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if heartbeat := got.GetHeartbeat(); heartbeat == nil || heartbeat.ClientID != "c1" {
+		t.Fatalf("GetHeartbeat() = %+v, want ClientID=c1", heartbeat)
+	}
+}
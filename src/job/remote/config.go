@@ -0,0 +1,83 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package remote implements an optional gRPC control-plane client: a long-lived bidirectional
+// stream to an operator-run coordinator that replaces config polling with server-streamed
+// JobConfig updates and augments local metrics with client-streamed heartbeats/stats.
+package remote
+
+import (
+	"flag"
+	"time"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+// Config describes how to reach the control-plane coordinator.
+type Config struct {
+	Enabled bool
+
+	Endpoint string
+
+	// TLSCertFile/TLSKeyFile configure client mTLS, leave empty to use the system cert pool only
+	TLSCertFile string
+	TLSKeyFile  string
+	CACertFile  string
+	Insecure    bool
+
+	// Token is sent as a per-RPC bearer credential, letting the coordinator authenticate workers
+	// without a client certificate
+	Token string
+
+	HealthAddr string
+
+	ReconnectBackoff  time.Duration
+	HeartbeatInterval time.Duration
+}
+
+// NewConfigWithFlags returns a config for the gRPC control plane client, registering flags the
+// same way the other NewXWithFlags constructors in this codebase do.
+func NewConfigWithFlags() *Config {
+	cfg := &Config{}
+
+	flag.BoolVar(&cfg.Enabled, "remote-control-enabled", utils.GetEnvBoolDefault("REMOTE_CONTROL_ENABLED", false),
+		"enable the gRPC control-plane client")
+	flag.StringVar(&cfg.Endpoint, "remote-control-endpoint", utils.GetEnvStringDefault("REMOTE_CONTROL_ENDPOINT", ""),
+		"address of the gRPC control-plane coordinator, e.g. coordinator.example.com:443")
+	flag.StringVar(&cfg.TLSCertFile, "remote-control-tls-cert", utils.GetEnvStringDefault("REMOTE_CONTROL_TLS_CERT", ""),
+		"client certificate to present to the coordinator")
+	flag.StringVar(&cfg.TLSKeyFile, "remote-control-tls-key", utils.GetEnvStringDefault("REMOTE_CONTROL_TLS_KEY", ""),
+		"private key matching -remote-control-tls-cert")
+	flag.StringVar(&cfg.CACertFile, "remote-control-ca-cert", utils.GetEnvStringDefault("REMOTE_CONTROL_CA_CERT", ""),
+		"CA bundle used to verify the coordinator, leave empty to use the system pool")
+	flag.BoolVar(&cfg.Insecure, "remote-control-insecure", utils.GetEnvBoolDefault("REMOTE_CONTROL_INSECURE", false),
+		"skip TLS entirely and dial the coordinator in plaintext")
+	flag.StringVar(&cfg.Token, "remote-control-token", utils.GetEnvStringDefault("REMOTE_CONTROL_TOKEN", ""),
+		"per-RPC bearer token sent to the coordinator")
+	flag.StringVar(&cfg.HealthAddr, "remote-control-health-addr", utils.GetEnvStringDefault("REMOTE_CONTROL_HEALTH_ADDR", ""),
+		"address to serve grpc.health.v1 on, defaults to the pprof endpoint when empty, or to :8090 if pprof is off too")
+
+	cfg.ReconnectBackoff = utils.GetEnvDurationDefault("REMOTE_CONTROL_RECONNECT_BACKOFF", 5*time.Second)
+	cfg.HeartbeatInterval = utils.GetEnvDurationDefault("REMOTE_CONTROL_HEARTBEAT_INTERVAL", 30*time.Second)
+
+	return cfg
+}
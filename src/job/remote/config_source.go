@@ -0,0 +1,58 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+)
+
+// ConfigSource implements job.ConfigSource on top of a Client's Sync stream: every Load call
+// blocks for the next coordinator-pushed JobConfig instead of re-fetching a URL.
+type ConfigSource struct {
+	client *Client
+}
+
+// NewConfigSource wraps client as a job.ConfigSource. Returns nil when client is nil (the
+// control plane is disabled), so callers can register it unconditionally.
+func NewConfigSource(client *Client) *ConfigSource {
+	if client == nil {
+		return nil
+	}
+
+	return &ConfigSource{client: client}
+}
+
+// Load blocks until the coordinator pushes the next JobConfig, or ctx is canceled.
+func (s *ConfigSource) Load(ctx context.Context) ([]byte, error) {
+	select {
+	case cfg, ok := <-s.client.Configs():
+		if !ok {
+			return nil, fmt.Errorf("control-plane stream closed")
+		}
+
+		return []byte(cfg.GetBody()), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,106 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Hand-written client/server stubs for the RemoteControl service described in remote.proto.
+// There's no protoc/buf run in this build, so these aren't codegen output — see codec.go for
+// how messages actually get serialized on the wire.
+package remote
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	RemoteControl_Sync_FullMethodName = "/remote.RemoteControl/Sync"
+)
+
+// RemoteControlClient is the client API for RemoteControl service.
+type RemoteControlClient interface {
+	Sync(ctx context.Context, opts ...grpc.CallOption) (RemoteControl_SyncClient, error)
+}
+
+type remoteControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRemoteControlClient(cc grpc.ClientConnInterface) RemoteControlClient {
+	return &remoteControlClient{cc}
+}
+
+func (c *remoteControlClient) Sync(ctx context.Context, opts ...grpc.CallOption) (RemoteControl_SyncClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RemoteControl_ServiceDesc.Streams[0], RemoteControl_Sync_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &remoteControlSyncClient{stream}, nil
+}
+
+type RemoteControl_SyncClient interface {
+	Send(*ControlMessage) error
+	Recv() (*JobConfig, error)
+	grpc.ClientStream
+}
+
+type remoteControlSyncClient struct {
+	grpc.ClientStream
+}
+
+func (x *remoteControlSyncClient) Send(m *ControlMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *remoteControlSyncClient) Recv() (*JobConfig, error) {
+	m := new(JobConfig)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// RemoteControlServer is the server API for RemoteControl service.
+type RemoteControlServer interface {
+	Sync(RemoteControl_SyncServer) error
+}
+
+type RemoteControl_SyncServer interface {
+	Send(*JobConfig) error
+	Recv() (*ControlMessage, error)
+	grpc.ServerStream
+}
+
+var RemoteControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "remote.RemoteControl",
+	HandlerType: (*RemoteControlServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Sync",
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "src/job/remote/remote.proto",
+}
@@ -0,0 +1,157 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// remote.proto's messages are hand-written rather than run through protoc: the wire format is
+// plain JSON (see codec.go), not the protobuf wire format, so these types deliberately don't
+// implement proto.Message.
+package remote
+
+import "encoding/json"
+
+// JobConfig mirrors the JSON/YAML config payload the runner otherwise pulls
+// over HTTP(S) from runnerConfigOptions.PathsCSV.
+type JobConfig struct {
+	Body     string `json:"body,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Version  string `json:"version,omitempty"`
+}
+
+func (m *JobConfig) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+
+	return ""
+}
+
+func (m *JobConfig) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+
+	return ""
+}
+
+func (m *JobConfig) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+
+	return ""
+}
+
+// TargetStats is one target's worth of the numbers metrics.Reporter already tracks locally.
+type TargetStats struct {
+	Target   string `json:"target,omitempty"`
+	Requests uint64 `json:"requests,omitempty"`
+	Bytes    uint64 `json:"bytes,omitempty"`
+	Errors   uint64 `json:"errors,omitempty"`
+}
+
+func (m *TargetStats) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+
+	return ""
+}
+
+// ClientHeartbeat lets the coordinator track liveness and aggregate stats across the fleet
+// without every worker pushing to its own Prometheus endpoint.
+type ClientHeartbeat struct {
+	ClientID string         `json:"client_id,omitempty"`
+	Version  string         `json:"version,omitempty"`
+	Stats    []*TargetStats `json:"stats,omitempty"`
+}
+
+// ControlMessage is the single client->server message type so Sync can stay one bidi stream
+// instead of juggling two.
+type ControlMessage struct {
+	// Types that are valid to be assigned to Payload:
+	//	*ControlMessage_Heartbeat
+	//	*ControlMessage_Stats
+	Payload isControlMessage_Payload
+}
+
+type isControlMessage_Payload interface{ isControlMessage_Payload() }
+
+type ControlMessage_Heartbeat struct {
+	Heartbeat *ClientHeartbeat
+}
+
+type ControlMessage_Stats struct {
+	Stats *TargetStats
+}
+
+func (*ControlMessage_Heartbeat) isControlMessage_Payload() {}
+func (*ControlMessage_Stats) isControlMessage_Payload()     {}
+
+func (m *ControlMessage) GetHeartbeat() *ClientHeartbeat {
+	if h, ok := m.GetPayload().(*ControlMessage_Heartbeat); ok {
+		return h.Heartbeat
+	}
+
+	return nil
+}
+
+func (m *ControlMessage) GetStats() *TargetStats {
+	if s, ok := m.GetPayload().(*ControlMessage_Stats); ok {
+		return s.Stats
+	}
+
+	return nil
+}
+
+func (m *ControlMessage) GetPayload() isControlMessage_Payload {
+	if m != nil {
+		return m.Payload
+	}
+
+	return nil
+}
+
+// controlMessageWire is ControlMessage's on-the-wire JSON shape: a plain struct works with
+// encoding/json, unlike the Payload oneof interface.
+type controlMessageWire struct {
+	Heartbeat *ClientHeartbeat `json:"heartbeat,omitempty"`
+	Stats     *TargetStats     `json:"stats,omitempty"`
+}
+
+func (m *ControlMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(controlMessageWire{Heartbeat: m.GetHeartbeat(), Stats: m.GetStats()})
+}
+
+func (m *ControlMessage) UnmarshalJSON(data []byte) error {
+	var wire controlMessageWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	switch {
+	case wire.Heartbeat != nil:
+		m.Payload = &ControlMessage_Heartbeat{Heartbeat: wire.Heartbeat}
+	case wire.Stats != nil:
+		m.Payload = &ControlMessage_Stats{Stats: wire.Stats}
+	}
+
+	return nil
+}
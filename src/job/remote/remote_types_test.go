@@ -0,0 +1,122 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package remote
+
+import "testing"
+
+func TestControlMessageJSONRoundTripHeartbeat(t *testing.T) {
+	want := &ControlMessage{Payload: &ControlMessage_Heartbeat{
+		Heartbeat: &ClientHeartbeat{ClientID: "worker-1", Version: "v1.2.3", Stats: []*TargetStats{
+			{Target: "udp://example.com:1234", Requests: 5, Bytes: 512, Errors: 1},
+		}},
+	}}
+
+	data, err := want.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ControlMessage
+	if err := got.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	heartbeat := got.GetHeartbeat()
+	if heartbeat == nil {
+		t.Fatal("GetHeartbeat() = nil after round-tripping a heartbeat payload")
+	}
+
+	if heartbeat.ClientID != "worker-1" || heartbeat.Version != "v1.2.3" {
+		t.Errorf("heartbeat = %+v, want ClientID=worker-1 Version=v1.2.3", heartbeat)
+	}
+
+	if len(heartbeat.Stats) != 1 || heartbeat.Stats[0].Target != "udp://example.com:1234" {
+		t.Errorf("heartbeat.Stats = %+v, want one entry for udp://example.com:1234", heartbeat.Stats)
+	}
+
+	if got.GetStats() != nil {
+		t.Errorf("GetStats() = %+v, want nil for a heartbeat payload", got.GetStats())
+	}
+}
+
+func TestControlMessageJSONRoundTripStats(t *testing.T) {
+	want := &ControlMessage{Payload: &ControlMessage_Stats{
+		Stats: &TargetStats{Target: "quic://example.com:443", Requests: 42, Bytes: 1024, Errors: 0},
+	}}
+
+	data, err := want.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	var got ControlMessage
+	if err := got.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	stats := got.GetStats()
+	if stats == nil {
+		t.Fatal("GetStats() = nil after round-tripping a stats payload")
+	}
+
+	if stats.Target != "quic://example.com:443" || stats.Requests != 42 {
+		t.Errorf("stats = %+v, want Target=quic://example.com:443 Requests=42", stats)
+	}
+
+	if got.GetHeartbeat() != nil {
+		t.Errorf("GetHeartbeat() = %+v, want nil for a stats payload", got.GetHeartbeat())
+	}
+}
+
+func TestControlMessageJSONRoundTripEmpty(t *testing.T) {
+	var got ControlMessage
+	if err := got.UnmarshalJSON([]byte(`{}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if got.GetPayload() != nil {
+		t.Errorf("GetPayload() = %+v, want nil for an empty message", got.GetPayload())
+	}
+}
+
+func TestTargetStatsNilGetters(t *testing.T) {
+	var stats *TargetStats
+	if got := stats.GetTarget(); got != "" {
+		t.Errorf("(*TargetStats)(nil).GetTarget() = %q, want empty string", got)
+	}
+}
+
+func TestJobConfigNilGetters(t *testing.T) {
+	var cfg *JobConfig
+	if got := cfg.GetBody(); got != "" {
+		t.Errorf("(*JobConfig)(nil).GetBody() = %q, want empty string", got)
+	}
+
+	if got := cfg.GetEncoding(); got != "" {
+		t.Errorf("(*JobConfig)(nil).GetEncoding() = %q, want empty string", got)
+	}
+
+	if got := cfg.GetVersion(); got != "" {
+		t.Errorf("(*JobConfig)(nil).GetVersion() = %q, want empty string", got)
+	}
+}